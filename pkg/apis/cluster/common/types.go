@@ -0,0 +1,30 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+// MachineDeploymentStrategyType defines the type of MachineDeployment rollout strategies.
+type MachineDeploymentStrategyType string
+
+const (
+	// RollingUpdateMachineDeploymentStrategyType replaces the old MachineSet by new one using rolling update
+	// i.e gradually scale down the old MachineSet and scale up the new one.
+	RollingUpdateMachineDeploymentStrategyType MachineDeploymentStrategyType = "RollingUpdate"
+
+	// RecreateMachineDeploymentStrategyType replaces the old MachineSet by new one using the "recreate"
+	// strategy, i.e. scale down the old MachineSet to zero before scaling up the new one.
+	RecreateMachineDeploymentStrategyType MachineDeploymentStrategyType = "Recreate"
+)
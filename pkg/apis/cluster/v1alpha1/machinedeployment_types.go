@@ -0,0 +1,175 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/common"
+)
+
+// MachineDeployment is the Schema for the machinedeployments API.
+type MachineDeployment struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MachineDeploymentSpec   `json:"spec,omitempty"`
+	Status MachineDeploymentStatus `json:"status,omitempty"`
+}
+
+// MachineDeploymentList contains a list of MachineDeployment.
+type MachineDeploymentList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MachineDeployment `json:"items"`
+}
+
+// MachineDeploymentSpec defines the desired state of MachineDeployment.
+type MachineDeploymentSpec struct {
+	// Replicas is the number of desired Machines.
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// Selector is the label selector for Machines.
+	Selector metav1.LabelSelector `json:"selector"`
+
+	// Template describes the Machines that will be created.
+	Template MachineTemplateSpec `json:"template"`
+
+	// Strategy describes how to replace existing Machines with new ones.
+	Strategy MachineDeploymentStrategy `json:"strategy,omitempty"`
+
+	// MinReadySeconds is the minimum number of seconds for which a newly created Machine should
+	// be ready, without any of its container crashing, for it to be considered available.
+	MinReadySeconds *int32 `json:"minReadySeconds,omitempty"`
+
+	// RevisionHistoryLimit is the number of old MachineSets to retain to allow rollback.
+	RevisionHistoryLimit *int32 `json:"revisionHistoryLimit,omitempty"`
+
+	// ProgressDeadlineSeconds is the maximum time in seconds for a deployment to make progress
+	// before it is considered to be failed. The deployment controller will continue to process
+	// failed deployments and a condition with a ProgressDeadlineExceeded reason will be surfaced
+	// in the deployment status. Once autoRollback is implemented, the deployment controller will
+	// automatically rollback failed deployments. Defaults to 600s.
+	ProgressDeadlineSeconds *int32 `json:"progressDeadlineSeconds,omitempty"`
+
+	// RollbackTo, if set, requests a rollback to the given revision. The deployment controller
+	// clears this field once the rollback has been processed.
+	RollbackTo *RollbackConfig `json:"rollbackTo,omitempty"`
+
+	// Paused indicates that the deployment is paused. While paused, the controller keeps the
+	// deployment's status and machine set sizing up to date but does not trigger a rollout, so
+	// that a template change (e.g. a new AMI) can be staged and released later by unpausing.
+	// While no machine set matches the current template, a paused deployment's machine sets are
+	// not resized and its status is not updated.
+	Paused bool `json:"paused,omitempty"`
+}
+
+// RollbackConfig describes the revision a MachineDeployment should roll back to.
+type RollbackConfig struct {
+	// Revision to roll back to. If set to 0, the last revision before the current one is used.
+	Revision int64 `json:"revision,omitempty"`
+}
+
+// MachineDeploymentStrategy describes how to replace existing Machines with new ones.
+type MachineDeploymentStrategy struct {
+	// Type of deployment. Can be "RollingUpdate" or "Recreate". Default is RollingUpdate.
+	Type common.MachineDeploymentStrategyType `json:"type,omitempty"`
+
+	// RollingUpdate is the rolling update config params. Present only if MachineDeploymentStrategyType =
+	// RollingUpdate.
+	RollingUpdate *MachineRollingUpdateDeployment `json:"rollingUpdate,omitempty"`
+}
+
+// MachineRollingUpdateDeployment is the spec to control the desired behavior of rolling update.
+type MachineRollingUpdateDeployment struct {
+	// The maximum number of Machines that can be unavailable during the update.
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+
+	// The maximum number of Machines that can be scheduled above the desired number of
+	// Machines.
+	MaxSurge *intstr.IntOrString `json:"maxSurge,omitempty"`
+}
+
+// MachineDeploymentStatus defines the observed state of MachineDeployment.
+type MachineDeploymentStatus struct {
+	// ObservedGeneration is the generation observed by the deployment controller.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Replicas is the total number of non-terminated Machines targeted by this deployment
+	// (their labels match the selector).
+	Replicas int32 `json:"replicas,omitempty"`
+
+	// UpdatedReplicas is the total number of non-terminated Machines targeted by this deployment
+	// that have the desired template spec.
+	UpdatedReplicas int32 `json:"updatedReplicas,omitempty"`
+
+	// ReadyReplicas is the total number of ready Machines targeted by this deployment.
+	ReadyReplicas int32 `json:"readyReplicas,omitempty"`
+
+	// AvailableReplicas is the total number of available Machines (ready for at least
+	// minReadySeconds) targeted by this deployment.
+	AvailableReplicas int32 `json:"availableReplicas,omitempty"`
+
+	// UnavailableReplicas is the total number of unavailable Machines targeted by this deployment.
+	UnavailableReplicas int32 `json:"unavailableReplicas,omitempty"`
+
+	// Conditions represents the latest available observations of a deployment's current state.
+	Conditions []MachineDeploymentCondition `json:"conditions,omitempty"`
+}
+
+// MachineDeploymentConditionType defines the aspect of deployment state that a
+// MachineDeploymentCondition describes.
+type MachineDeploymentConditionType string
+
+const (
+	// MachineDeploymentAvailable means the deployment is available, i.e. at least the minimum
+	// available Machines required are up and running for at least minReadySeconds.
+	MachineDeploymentAvailable MachineDeploymentConditionType = "Available"
+
+	// MachineDeploymentProgressing means the deployment is progressing, i.e. a new machine set
+	// is being created or scaled up, an old machine set is being scaled down, or new Machines
+	// become available. It is False when progress has stalled past ProgressDeadlineSeconds.
+	MachineDeploymentProgressing MachineDeploymentConditionType = "Progressing"
+
+	// MachineDeploymentReplicaFailure is added while the new machine set reports a terminal
+	// problem reconciling its replicas in its ErrorReason/ErrorMessage status fields, which the
+	// condition carries as its reason and message.
+	MachineDeploymentReplicaFailure MachineDeploymentConditionType = "ReplicaFailure"
+)
+
+// MachineDeploymentCondition describes the state of a deployment at a certain point.
+type MachineDeploymentCondition struct {
+	// Type of deployment condition.
+	Type MachineDeploymentConditionType `json:"type"`
+
+	// Status of the condition, one of True, False, Unknown.
+	Status corev1.ConditionStatus `json:"status"`
+
+	// The last time this condition was updated.
+	LastUpdateTime metav1.Time `json:"lastUpdateTime,omitempty"`
+
+	// Last time the condition transitioned from one status to another.
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+
+	// The reason for the condition's last transition.
+	Reason string `json:"reason,omitempty"`
+
+	// A human readable message indicating details about the transition.
+	Message string `json:"message,omitempty"`
+}
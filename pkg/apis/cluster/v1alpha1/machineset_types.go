@@ -0,0 +1,71 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// MachineSet is the Schema for the machinesets API.
+type MachineSet struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MachineSetSpec   `json:"spec,omitempty"`
+	Status MachineSetStatus `json:"status,omitempty"`
+}
+
+// MachineSetList contains a list of MachineSet.
+type MachineSetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MachineSet `json:"items"`
+}
+
+// MachineSetSpec defines the desired state of MachineSet.
+type MachineSetSpec struct {
+	// Replicas is the number of desired replicas.
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// MinReadySeconds is the minimum number of seconds for which a newly created machine should
+	// be ready for it to be considered available.
+	MinReadySeconds int32 `json:"minReadySeconds,omitempty"`
+
+	Selector metav1.LabelSelector `json:"selector"`
+
+	Template MachineTemplateSpec `json:"template,omitempty"`
+}
+
+// MachineSetStatus defines the observed state of MachineSet.
+type MachineSetStatus struct {
+	// Replicas is the most recently observed number of replicas.
+	Replicas int32 `json:"replicas"`
+
+	// The number of ready replicas for this MachineSet.
+	ReadyReplicas int32 `json:"readyReplicas,omitempty"`
+
+	// The number of available replicas (ready for at least minReadySeconds) for this MachineSet.
+	AvailableReplicas int32 `json:"availableReplicas,omitempty"`
+
+	// ObservedGeneration reflects the generation of the most recently observed MachineSet.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// In the event that there is a terminal problem reconciling the replicas, both
+	// ErrorReason and ErrorMessage will be set.
+	ErrorReason  *string `json:"errorReason,omitempty"`
+	ErrorMessage *string `json:"errorMessage,omitempty"`
+}
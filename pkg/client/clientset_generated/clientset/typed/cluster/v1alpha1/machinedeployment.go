@@ -0,0 +1,45 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+)
+
+// ClusterV1alpha1Interface exposes the typed clients for the cluster/v1alpha1 API group.
+type ClusterV1alpha1Interface interface {
+	MachineDeployments(namespace string) MachineDeploymentInterface
+	MachineSets(namespace string) MachineSetInterface
+}
+
+// MachineDeploymentInterface has methods to work with MachineDeployment resources.
+type MachineDeploymentInterface interface {
+	Get(name string, options metav1.GetOptions) (*v1alpha1.MachineDeployment, error)
+	Update(*v1alpha1.MachineDeployment) (*v1alpha1.MachineDeployment, error)
+	UpdateStatus(*v1alpha1.MachineDeployment) (*v1alpha1.MachineDeployment, error)
+}
+
+// MachineSetInterface has methods to work with MachineSet resources.
+type MachineSetInterface interface {
+	Get(name string, options metav1.GetOptions) (*v1alpha1.MachineSet, error)
+	Create(*v1alpha1.MachineSet) (*v1alpha1.MachineSet, error)
+	Update(*v1alpha1.MachineSet) (*v1alpha1.MachineSet, error)
+	UpdateStatus(*v1alpha1.MachineSet) (*v1alpha1.MachineSet, error)
+	Delete(name string, options *metav1.DeleteOptions) error
+}
@@ -0,0 +1,204 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machinedeployment
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/golang/glog"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/common"
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+	"sigs.k8s.io/cluster-api/pkg/client/clientset_generated/clientset"
+	dutil "sigs.k8s.io/cluster-api/pkg/controller/machinedeployment/util"
+)
+
+// MachineDeploymentControllerImpl reconciles MachineDeployments by driving their owned
+// MachineSets towards the desired state, mirroring the apps/v1 Deployment controller.
+type MachineDeploymentControllerImpl struct {
+	machineClient clientset.Interface
+	recorder      record.EventRecorder
+}
+
+// Reconcile implements the top-level MachineDeployment sync loop.
+func (dc *MachineDeploymentControllerImpl) Reconcile(d *v1alpha1.MachineDeployment) error {
+	msList, machineMap, err := dc.getMachineSetsAndMachinesForDeployment(d)
+	if err != nil {
+		return err
+	}
+
+	if d.Spec.Paused {
+		return dc.sync(d, msList, machineMap)
+	}
+
+	if d.Spec.RollbackTo != nil {
+		// A rollback fully handles the reconcile on its own (it either rolls the template back
+		// and lets the next reconcile drive the resulting rollout, or records why it couldn't);
+		// running a rollout in the same pass would race the rollback's own machine set changes.
+		return dc.rollback(d, msList, machineMap)
+	}
+
+	if d.Spec.Strategy.Type == common.RecreateMachineDeploymentStrategyType {
+		return dc.rolloutRecreate(d, msList, machineMap)
+	}
+	return dc.rolloutRolling(d, msList, machineMap)
+}
+
+// getMachineSetsAndMachinesForDeployment is a placeholder for the listing logic that fetches all
+// MachineSets and Machines owned by d. The real implementation lists via the shared informers.
+func (dc *MachineDeploymentControllerImpl) getMachineSetsAndMachinesForDeployment(d *v1alpha1.MachineDeployment) ([]*v1alpha1.MachineSet, map[types.UID]*v1alpha1.MachineList, error) {
+	return nil, nil, nil
+}
+
+// getAllMachineSetsAndSyncRevision returns the new MachineSet for the deployment and the list of
+// old MachineSets, after syncing the deployment.kubernetes.io/revision annotation onto the new
+// one. If no new MachineSet exists yet and createIfNotExisted is true, one is created from d's
+// template.
+func (dc *MachineDeploymentControllerImpl) getAllMachineSetsAndSyncRevision(d *v1alpha1.MachineDeployment, msList []*v1alpha1.MachineSet, machineMap map[types.UID]*v1alpha1.MachineList, createIfNotExisted bool) (*v1alpha1.MachineSet, []*v1alpha1.MachineSet, error) {
+	newMS := dutil.FindNewMachineSet(d, msList)
+	oldMSs := dutil.FindOldMachineSets(d, msList, newMS)
+
+	// The new machine set's revision is always one past the highest revision among the old ones;
+	// old machine sets keep whatever revision they were stamped with when they were themselves
+	// the new machine set.
+	newRevision := strconv.FormatInt(dutil.MaxRevision(oldMSs)+1, 10)
+
+	if newMS != nil {
+		updatedNewMS, err := dc.syncMachineSetRevision(newMS, newRevision)
+		if err != nil {
+			return nil, oldMSs, err
+		}
+		return updatedNewMS, oldMSs, nil
+	}
+
+	if !createIfNotExisted {
+		return nil, oldMSs, nil
+	}
+
+	createdMS, err := dc.createMachineSetFromTemplate(d, newRevision)
+	if err != nil {
+		return nil, oldMSs, err
+	}
+	return createdMS, oldMSs, nil
+}
+
+// syncMachineSetRevision stamps ms with newRevision if its current RevisionAnnotation is lower,
+// mirroring SetNewReplicaSetAnnotations for apps/v1 Deployments. It makes no API call once ms
+// already carries a revision at least as high as newRevision.
+func (dc *MachineDeploymentControllerImpl) syncMachineSetRevision(ms *v1alpha1.MachineSet, newRevision string) (*v1alpha1.MachineSet, error) {
+	oldRevision := ms.Annotations[dutil.RevisionAnnotation]
+	oldRevisionInt, err := strconv.ParseInt(oldRevision, 10, 64)
+	if err != nil {
+		if oldRevision != "" {
+			return ms, nil
+		}
+		oldRevisionInt = 0
+	}
+	newRevisionInt, err := strconv.ParseInt(newRevision, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	if oldRevisionInt >= newRevisionInt {
+		return ms, nil
+	}
+
+	if ms.Annotations == nil {
+		ms.Annotations = make(map[string]string)
+	}
+	ms.Annotations[dutil.RevisionAnnotation] = newRevision
+	return dc.machineClient.ClusterV1alpha1().MachineSets(ms.Namespace).Update(ms)
+}
+
+// createMachineSetFromTemplate creates a new, initially-unscaled MachineSet from d's template,
+// stamped with newRevision, for the rollout to then scale up.
+func (dc *MachineDeploymentControllerImpl) createMachineSetFromTemplate(d *v1alpha1.MachineDeployment, newRevision string) (*v1alpha1.MachineSet, error) {
+	zero := int32(0)
+	newMS := &v1alpha1.MachineSet{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: d.Name + "-",
+			Namespace:    d.Namespace,
+			Annotations:  map[string]string{dutil.RevisionAnnotation: newRevision},
+		},
+		Spec: v1alpha1.MachineSetSpec{
+			Replicas: &zero,
+			Selector: d.Spec.Selector,
+			Template: d.Spec.Template,
+		},
+	}
+	return dc.machineClient.ClusterV1alpha1().MachineSets(d.Namespace).Create(newMS)
+}
+
+// scaleMachineSet sets ms.Spec.Replicas to newScale and persists the change, returning whether a
+// scaling operation was actually performed along with the updated MachineSet.
+func (dc *MachineDeploymentControllerImpl) scaleMachineSet(ms *v1alpha1.MachineSet, newScale int32, deployment *v1alpha1.MachineDeployment) (bool, *v1alpha1.MachineSet, error) {
+	if ms.Spec.Replicas != nil && *ms.Spec.Replicas == newScale {
+		return false, ms, nil
+	}
+
+	oldScale := int32(0)
+	if ms.Spec.Replicas != nil {
+		oldScale = *ms.Spec.Replicas
+	}
+	ms.Spec.Replicas = &newScale
+	updated, err := dc.machineClient.ClusterV1alpha1().MachineSets(ms.Namespace).Update(ms)
+	if err != nil {
+		return false, ms, err
+	}
+
+	direction := "up"
+	if newScale < oldScale {
+		direction = "down"
+	}
+	dc.recorder.Eventf(deployment, "Normal", "ScalingMachineSet", "Scaled %s machine set %s to %d", direction, ms.Name, newScale)
+	return true, updated, nil
+}
+
+// cleanupDeployment deletes old MachineSets beyond RevisionHistoryLimit that have zero replicas.
+func (dc *MachineDeploymentControllerImpl) cleanupDeployment(oldMSs []*v1alpha1.MachineSet, deployment *v1alpha1.MachineDeployment) error {
+	if deployment.Spec.RevisionHistoryLimit == nil {
+		return nil
+	}
+
+	var cleanableMSes []*v1alpha1.MachineSet
+	for _, ms := range oldMSs {
+		if ms.Spec.Replicas != nil && *ms.Spec.Replicas != 0 {
+			continue
+		}
+		cleanableMSes = append(cleanableMSes, ms)
+	}
+
+	diff := int32(len(cleanableMSes)) - *deployment.Spec.RevisionHistoryLimit
+	if diff <= 0 {
+		return nil
+	}
+
+	sort.Sort(dutil.MachineSetsByCreationTimestamp(cleanableMSes))
+	for i := int32(0); i < diff; i++ {
+		ms := cleanableMSes[i]
+		glog.V(4).Infof("Trying to cleanup machine set %q for deployment %q", ms.Name, deployment.Name)
+		if err := dc.machineClient.ClusterV1alpha1().MachineSets(ms.Namespace).Delete(ms.Name, &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
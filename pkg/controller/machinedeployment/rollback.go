@@ -0,0 +1,105 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machinedeployment
+
+import (
+	"github.com/golang/glog"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+	dutil "sigs.k8s.io/cluster-api/pkg/controller/machinedeployment/util"
+)
+
+const (
+	// RollbackRevisionNotFound is the event reason emitted when the requested revision could not
+	// be found among the deployment's machine sets.
+	RollbackRevisionNotFound = "DeploymentRollbackRevisionNotFound"
+
+	// RollbackTemplateUnchanged is the event reason emitted when the target revision's template
+	// already matches the current one, making the rollback a no-op.
+	RollbackTemplateUnchanged = "DeploymentRollbackTemplateUnchanged"
+
+	// RollbackDone is the event reason emitted once a rollback has been performed.
+	RollbackDone = "DeploymentRollback"
+)
+
+// rollback rolls back a MachineDeployment to the revision named by d.Spec.RollbackTo, mirroring
+// the `kubectl rollout undo` semantics for apps/v1 Deployments.
+func (dc *MachineDeploymentControllerImpl) rollback(d *v1alpha1.MachineDeployment, msList []*v1alpha1.MachineSet, machineMap map[types.UID]*v1alpha1.MachineList) error {
+	newMS, allOldMSs, err := dc.getAllMachineSetsAndSyncRevision(d, msList, machineMap, true)
+	if err != nil {
+		return err
+	}
+
+	allMSs := append(allOldMSs, newMS)
+	rollbackTo := d.Spec.RollbackTo
+	if rollbackTo.Revision == 0 {
+		if rollbackTo.Revision = dutil.LastRevision(allMSs); rollbackTo.Revision == 0 {
+			// no last revision to rollback to
+			dc.recorder.Eventf(d, "Warning", RollbackRevisionNotFound, "Unable to find last revision.")
+			return dc.updateDeploymentAndClearRollbackTo(d)
+		}
+	}
+	for _, ms := range allMSs {
+		v, err := dutil.Revision(ms)
+		if err != nil {
+			glog.V(4).Infof("Unable to extract revision from machine set %q: %v", ms.Name, err)
+			continue
+		}
+		if v != rollbackTo.Revision {
+			continue
+		}
+
+		if v == dutil.MaxRevision(allMSs) {
+			// The revision to rollback to is the same as the current one; nothing to do.
+			dc.recorder.Eventf(d, "Warning", RollbackTemplateUnchanged, "Rolling back to a revision that contains the same template as current machine deployment %q", d.Name)
+			return dc.updateDeploymentAndClearRollbackTo(d)
+		}
+
+		return dc.rollbackToTemplate(d, ms)
+	}
+	dc.recorder.Eventf(d, "Warning", RollbackRevisionNotFound, "Unable to find the revision to rollback to.")
+	return dc.updateDeploymentAndClearRollbackTo(d)
+}
+
+// rollbackToTemplate copies ms's template back into d and sets d's revision annotation to ms's
+// revision.
+func (dc *MachineDeploymentControllerImpl) rollbackToTemplate(d *v1alpha1.MachineDeployment, ms *v1alpha1.MachineSet) error {
+	performedRollback := false
+	if !dutil.EqualMachineTemplate(&d.Spec.Template, &ms.Spec.Template) {
+		glog.V(4).Infof("Rolling back machine deployment %q to template spec of machine set %q", d.Name, ms.Name)
+		dutil.SetFromMachineSetTemplate(d, ms.Spec.Template)
+		dutil.SetDeploymentRevision(d, ms.Annotations[dutil.RevisionAnnotation])
+		performedRollback = true
+	} else {
+		glog.V(4).Infof("Rolling back to a revision that contains the same template as current machine deployment %q, skipping rollback", d.Name)
+	}
+
+	if performedRollback {
+		dc.recorder.Eventf(d, "Normal", RollbackDone, "Rolled back machine deployment %q to revision %s", d.Name, ms.Annotations[dutil.RevisionAnnotation])
+	}
+	return dc.updateDeploymentAndClearRollbackTo(d)
+}
+
+// updateDeploymentAndClearRollbackTo clears d.Spec.RollbackTo and persists the change.
+func (dc *MachineDeploymentControllerImpl) updateDeploymentAndClearRollbackTo(d *v1alpha1.MachineDeployment) error {
+	glog.V(4).Infof("Cleaning up rollbackTo of machine deployment %q", d.Name)
+	d.Spec.RollbackTo = nil
+	_, err := dc.machineClient.ClusterV1alpha1().MachineDeployments(d.Namespace).Update(d)
+	return err
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machinedeployment
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+	dutil "sigs.k8s.io/cluster-api/pkg/controller/machinedeployment/util"
+)
+
+// machineTemplate returns a MachineTemplateSpec distinguishable from others only by name, so
+// EqualMachineTemplate (which compares Spec alone) treats differently-named templates as distinct.
+func machineTemplate(name string) v1alpha1.MachineTemplateSpec {
+	return v1alpha1.MachineTemplateSpec{
+		Spec: v1alpha1.MachineSpec{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+		},
+	}
+}
+
+// rollbackFixture builds a MachineDeployment currently running templateV2 via newMS, with a
+// single old machine set at revision 1 running templateV1.
+func rollbackFixture() (*v1alpha1.MachineDeployment, []*v1alpha1.MachineSet, *MachineDeploymentControllerImpl) {
+	d := &v1alpha1.MachineDeployment{
+		Spec: v1alpha1.MachineDeploymentSpec{
+			Template: machineTemplate("v2"),
+		},
+	}
+
+	oldMS := &v1alpha1.MachineSet{}
+	oldMS.Name = "old"
+	oldMS.UID = types.UID("old")
+	oldMS.Annotations = map[string]string{dutil.RevisionAnnotation: "1"}
+	oldMS.Spec.Template = machineTemplate("v1")
+
+	newMS := &v1alpha1.MachineSet{}
+	newMS.Name = "new"
+	newMS.UID = types.UID("new")
+	newMS.Spec.Template = machineTemplate("v2")
+
+	dc := &MachineDeploymentControllerImpl{
+		machineClient: &fakeClientset{},
+		recorder:      record.NewFakeRecorder(10),
+	}
+
+	return d, []*v1alpha1.MachineSet{oldMS, newMS}, dc
+}
+
+func TestRollbackToMissingRevisionClearsRollbackTo(t *testing.T) {
+	d, msList, dc := rollbackFixture()
+	d.Spec.RollbackTo = &v1alpha1.RollbackConfig{Revision: 5}
+
+	if err := dc.rollback(d, msList, nil); err != nil {
+		t.Fatalf("rollback() returned error: %v", err)
+	}
+
+	if d.Spec.RollbackTo != nil {
+		t.Errorf("RollbackTo = %v, want cleared", d.Spec.RollbackTo)
+	}
+	if d.Spec.Template.Spec.Name != "v2" {
+		t.Errorf("Template = %q, want unchanged (v2)", d.Spec.Template.Spec.Name)
+	}
+}
+
+func TestRollbackToCurrentRevisionIsANoop(t *testing.T) {
+	d, msList, dc := rollbackFixture()
+	// newMS (revision 2, once synced) already holds the current template, so rolling back to it
+	// should be recognized as a no-op rather than rewriting the template in place.
+	d.Spec.RollbackTo = &v1alpha1.RollbackConfig{Revision: 2}
+
+	if err := dc.rollback(d, msList, nil); err != nil {
+		t.Fatalf("rollback() returned error: %v", err)
+	}
+
+	if d.Spec.RollbackTo != nil {
+		t.Errorf("RollbackTo = %v, want cleared", d.Spec.RollbackTo)
+	}
+	if d.Spec.Template.Spec.Name != "v2" {
+		t.Errorf("Template = %q, want unchanged (v2)", d.Spec.Template.Spec.Name)
+	}
+}
+
+func TestRollbackToOldRevisionRestoresItsTemplate(t *testing.T) {
+	d, msList, dc := rollbackFixture()
+	d.Spec.RollbackTo = &v1alpha1.RollbackConfig{Revision: 1}
+
+	if err := dc.rollback(d, msList, nil); err != nil {
+		t.Fatalf("rollback() returned error: %v", err)
+	}
+
+	if d.Spec.RollbackTo != nil {
+		t.Errorf("RollbackTo = %v, want cleared", d.Spec.RollbackTo)
+	}
+	if d.Spec.Template.Spec.Name != "v1" {
+		t.Errorf("Template = %q, want rolled back to v1", d.Spec.Template.Spec.Name)
+	}
+	if got := d.Annotations[dutil.RevisionAnnotation]; got != "1" {
+		t.Errorf("deployment revision annotation = %q, want %q", got, "1")
+	}
+}
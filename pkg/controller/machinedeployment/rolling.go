@@ -37,6 +37,12 @@ func (dc *MachineDeploymentControllerImpl) rolloutRolling(d *v1alpha1.MachineDep
 	}
 	allMSs := append(oldMSs, newMS)
 
+	// Distribute any pending resize proportionally across old and new machine sets before
+	// reconciling either of them individually.
+	if err := dc.scale(d, msList, newMS); err != nil {
+		return err
+	}
+
 	// Scale up, if we can.
 	newMS, err = dc.reconcileNewMachineSet(allMSs, newMS, d)
 	if err != nil {
@@ -59,6 +65,67 @@ func (dc *MachineDeploymentControllerImpl) rolloutRolling(d *v1alpha1.MachineDep
 	return err
 }
 
+// rolloutRecreate implements the logic for recreating a machine deployment's machines: all old
+// machine sets are scaled down to zero, and the new machine set is scaled up only once they report
+// no available Machines, so that no two available Machines from different revisions run side by
+// side. This is required by infrastructure
+// providers where two Machines sharing an identity/IP/hostname cannot coexist.
+func (dc *MachineDeploymentControllerImpl) rolloutRecreate(d *v1alpha1.MachineDeployment, msList []*v1alpha1.MachineSet, machineMap map[types.UID]*v1alpha1.MachineList) error {
+	newMS, oldMSs, err := dc.getAllMachineSetsAndSyncRevision(d, msList, machineMap, true)
+	if err != nil {
+		return err
+	}
+	allMSs := append(oldMSs, newMS)
+
+	// Scale down old machine sets.
+	scaledDown, err := dc.scaleDownOldMachineSetsForRecreate(oldMSs, d)
+	if err != nil {
+		return err
+	}
+	if scaledDown {
+		_, err := dc.ensureStatus(d, newMS, oldMSs)
+		return err
+	}
+
+	// Do not proceed until every old machine set has no available machines left. Old Machines that
+	// still exist but are not available do not hold back the scale-up.
+	if dutil.GetAvailableReplicaCountForMachineSets(oldMSs) > 0 {
+		_, err := dc.ensureStatus(d, newMS, oldMSs)
+		return err
+	}
+
+	// Scale up the new machine set.
+	if _, err := dc.reconcileNewMachineSet(allMSs, newMS, d); err != nil {
+		return err
+	}
+
+	if dutil.DeploymentComplete(d, &d.Status) {
+		if err := dc.cleanupDeployment(oldMSs, d); err != nil {
+			return err
+		}
+	}
+
+	_, err = dc.ensureStatus(d, newMS, oldMSs)
+	return err
+}
+
+// scaleDownOldMachineSetsForRecreate scales every old machine set down to zero, returning whether
+// any scaling was actually performed.
+func (dc *MachineDeploymentControllerImpl) scaleDownOldMachineSetsForRecreate(oldMSs []*v1alpha1.MachineSet, deployment *v1alpha1.MachineDeployment) (bool, error) {
+	scaled := false
+	for _, ms := range oldMSs {
+		if ms.Spec.Replicas != nil && *ms.Spec.Replicas == 0 {
+			continue
+		}
+		scaledDown, _, err := dc.scaleMachineSet(ms, 0, deployment)
+		if err != nil {
+			return false, err
+		}
+		scaled = scaled || scaledDown
+	}
+	return scaled, nil
+}
+
 func (dc *MachineDeploymentControllerImpl) reconcileNewMachineSet(allMSs []*v1alpha1.MachineSet, newMS *v1alpha1.MachineSet, deployment *v1alpha1.MachineDeployment) (*v1alpha1.MachineSet, error) {
 	if deployment.Spec.Replicas == nil {
 		return newMS, fmt.Errorf("spec replicas for deployment set %v is nil, this is unexpected", deployment.Name)
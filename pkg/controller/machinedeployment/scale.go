@@ -0,0 +1,100 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machinedeployment
+
+import (
+	"sort"
+
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+	dutil "sigs.k8s.io/cluster-api/pkg/controller/machinedeployment/util"
+)
+
+// scale resizes newMS and the deployment's old machine sets, proportionally to their current size,
+// so that together they hold the allowed size: deployment.Spec.Replicas, plus MaxSurge while old
+// machine sets still hold replicas. This way a resize landing mid-rollout doesn't dump the
+// entire delta onto the new machine set and break the rolling invariant.
+func (dc *MachineDeploymentControllerImpl) scale(deployment *v1alpha1.MachineDeployment, msList []*v1alpha1.MachineSet, newMS *v1alpha1.MachineSet) error {
+	oldMSs := dutil.FindOldMachineSets(deployment, msList, newMS)
+	allMSs := append(oldMSs, newMS)
+
+	allowedSize := *(deployment.Spec.Replicas)
+	if dutil.GetReplicaCountForMachineSets(oldMSs) > 0 {
+		// The rollout is still in progress: allow surging above the desired size.
+		allowedSize = *(deployment.Spec.Replicas) + dutil.MaxSurge(*deployment)
+	}
+
+	allMSsReplicas := dutil.GetReplicaCountForMachineSets(allMSs)
+	deploymentReplicasToAdd := allowedSize - allMSsReplicas
+	if deploymentReplicasToAdd == 0 {
+		return nil
+	}
+
+	if deploymentReplicasToAdd < 0 {
+		// Trim unhealthy replicas out of the old machine sets first so that shrinking the
+		// deployment doesn't remove healthy capacity alongside them. This deliberately does not
+		// touch newMS: its unhealthy machines may simply still be coming up, so narrowing the
+		// cleanup to the old machine sets (rather than literally all of them) matches the
+		// upstream apps/v1 Deployment controller's own proportional scale-down behavior.
+		oldMSs, cleaned, err := dc.cleanupUnhealthyReplicas(oldMSs, deployment, -deploymentReplicasToAdd)
+		if err != nil {
+			return err
+		}
+		if cleaned > 0 {
+			allMSs = append(oldMSs, newMS)
+			allMSsReplicas = dutil.GetReplicaCountForMachineSets(allMSs)
+			deploymentReplicasToAdd = allowedSize - allMSsReplicas
+			if deploymentReplicasToAdd == 0 {
+				return nil
+			}
+		}
+	}
+
+	// Distribute the delta from the largest machine set down, breaking ties towards the newest
+	// machine set on scale-up and the oldest on scale-down, so leftover replicas land
+	// deterministically.
+	if deploymentReplicasToAdd > 0 {
+		sort.Sort(dutil.MachineSetsBySizeNewer(allMSs))
+	} else {
+		sort.Sort(dutil.MachineSetsBySizeOlder(allMSs))
+	}
+
+	deploymentReplicasAdded := int32(0)
+	nameToSize := make(map[string]int32, len(allMSs))
+	for _, ms := range allMSs {
+		proportion := dutil.GetProportion(ms, *deployment, allowedSize, allMSsReplicas, deploymentReplicasToAdd, deploymentReplicasAdded)
+		nameToSize[ms.Name] = *(ms.Spec.Replicas) + proportion
+		deploymentReplicasAdded += proportion
+	}
+
+	for i, ms := range allMSs {
+		if i == 0 {
+			// Any leftover from integer rounding is assigned to the machine set sorted first,
+			// i.e. the largest, and among equally large ones the newest on scale-up and the
+			// oldest on scale-down.
+			leftover := deploymentReplicasToAdd - deploymentReplicasAdded
+			nameToSize[ms.Name] += leftover
+			if nameToSize[ms.Name] < 0 {
+				nameToSize[ms.Name] = 0
+			}
+		}
+
+		if _, _, err := dc.scaleMachineSet(ms, nameToSize[ms.Name], deployment); err != nil {
+			return err
+		}
+	}
+	return nil
+}
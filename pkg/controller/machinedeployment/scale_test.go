@@ -0,0 +1,254 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machinedeployment
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
+
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/common"
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+	"sigs.k8s.io/cluster-api/pkg/client/clientset_generated/clientset"
+	clusterv1alpha1client "sigs.k8s.io/cluster-api/pkg/client/clientset_generated/clientset/typed/cluster/v1alpha1"
+)
+
+// fakeClientset is a no-op clientset.Interface sufficient for exercising the scale/cleanup logic:
+// Update calls simply echo back the (already locally mutated) object.
+type fakeClientset struct{}
+
+func (f *fakeClientset) ClusterV1alpha1() clusterv1alpha1client.ClusterV1alpha1Interface {
+	return fakeClusterV1alpha1{}
+}
+
+type fakeClusterV1alpha1 struct{}
+
+func (f fakeClusterV1alpha1) MachineDeployments(namespace string) clusterv1alpha1client.MachineDeploymentInterface {
+	return fakeMachineDeploymentClient{}
+}
+
+func (f fakeClusterV1alpha1) MachineSets(namespace string) clusterv1alpha1client.MachineSetInterface {
+	return fakeMachineSetClient{}
+}
+
+type fakeMachineDeploymentClient struct{}
+
+func (f fakeMachineDeploymentClient) Get(name string, options metav1.GetOptions) (*v1alpha1.MachineDeployment, error) {
+	return nil, nil
+}
+func (f fakeMachineDeploymentClient) Update(md *v1alpha1.MachineDeployment) (*v1alpha1.MachineDeployment, error) {
+	return md, nil
+}
+func (f fakeMachineDeploymentClient) UpdateStatus(md *v1alpha1.MachineDeployment) (*v1alpha1.MachineDeployment, error) {
+	return md, nil
+}
+
+type fakeMachineSetClient struct{}
+
+func (f fakeMachineSetClient) Get(name string, options metav1.GetOptions) (*v1alpha1.MachineSet, error) {
+	return nil, nil
+}
+func (f fakeMachineSetClient) Create(ms *v1alpha1.MachineSet) (*v1alpha1.MachineSet, error) {
+	return ms, nil
+}
+func (f fakeMachineSetClient) Update(ms *v1alpha1.MachineSet) (*v1alpha1.MachineSet, error) {
+	return ms, nil
+}
+func (f fakeMachineSetClient) UpdateStatus(ms *v1alpha1.MachineSet) (*v1alpha1.MachineSet, error) {
+	return ms, nil
+}
+func (f fakeMachineSetClient) Delete(name string, options *metav1.DeleteOptions) error {
+	return nil
+}
+
+var _ clientset.Interface = &fakeClientset{}
+
+func newTestMachineSet(name string, replicas, available int32, created time.Time) *v1alpha1.MachineSet {
+	ms := &v1alpha1.MachineSet{}
+	ms.Name = name
+	ms.UID = types.UID(name)
+	ms.CreationTimestamp = metav1.NewTime(created)
+	ms.Spec.Replicas = &replicas
+	ms.Status.Replicas = replicas
+	ms.Status.AvailableReplicas = available
+	return ms
+}
+
+func intstrPtr(i int) *intstr.IntOrString {
+	v := intstr.FromInt(i)
+	return &v
+}
+
+func TestScaleCleansUpUnhealthyReplicasBeforeProportionalTrim(t *testing.T) {
+	replicas := int32(6)
+	d := &v1alpha1.MachineDeployment{
+		Spec: v1alpha1.MachineDeploymentSpec{
+			Replicas: &replicas,
+			Strategy: v1alpha1.MachineDeploymentStrategy{
+				Type: common.RollingUpdateMachineDeploymentStrategyType,
+				RollingUpdate: &v1alpha1.MachineRollingUpdateDeployment{
+					MaxSurge:       intstrPtr(1),
+					MaxUnavailable: intstrPtr(0),
+				},
+			},
+		},
+	}
+
+	base := time.Unix(1600000000, 0)
+	oldMS1 := newTestMachineSet("old-1", 4, 4, base)                    // healthy
+	oldMS2 := newTestMachineSet("old-2", 2, 0, base.Add(time.Minute))   // fully unhealthy
+	newMS := newTestMachineSet("new", 4, 0, base.Add(2*time.Minute))    // unhealthy, but must be left alone
+
+	dc := &MachineDeploymentControllerImpl{
+		machineClient: &fakeClientset{},
+		recorder:      record.NewFakeRecorder(10),
+	}
+
+	if err := dc.scale(d, []*v1alpha1.MachineSet{oldMS1, oldMS2, newMS}, newMS); err != nil {
+		t.Fatalf("scale() returned error: %v", err)
+	}
+
+	if got := *oldMS2.Spec.Replicas; got != 0 {
+		t.Errorf("old-2 (unhealthy) replicas = %d, want 0 to be drained before the proportional trim", got)
+	}
+	if got := *newMS.Spec.Replicas; got != 4 {
+		t.Errorf("new machine set replicas = %d, want 4 (unhealthy replicas of the new MS must not be cleaned up here)", got)
+	}
+	if got := *oldMS1.Spec.Replicas; got != 3 {
+		t.Errorf("old-1 replicas = %d, want 3 after the remaining scale-down budget is trimmed proportionally", got)
+	}
+}
+
+// assertReplicas fails the test unless ms's Spec.Replicas equals want.
+func assertReplicas(t *testing.T, ms *v1alpha1.MachineSet, want int32) {
+	t.Helper()
+	if got := *ms.Spec.Replicas; got != want {
+		t.Errorf("%s replicas = %d, want %d", ms.Name, got, want)
+	}
+}
+
+func TestScaleDistributesProportionallyOnScaleUpDuringRollout(t *testing.T) {
+	replicas := int32(15)
+	d := &v1alpha1.MachineDeployment{
+		Spec: v1alpha1.MachineDeploymentSpec{
+			Replicas: &replicas,
+			Strategy: v1alpha1.MachineDeploymentStrategy{
+				Type: common.RollingUpdateMachineDeploymentStrategyType,
+				RollingUpdate: &v1alpha1.MachineRollingUpdateDeployment{
+					MaxSurge:       intstrPtr(1),
+					MaxUnavailable: intstrPtr(0),
+				},
+			},
+		},
+	}
+
+	base := time.Unix(1600000000, 0)
+	oldMS1 := newTestMachineSet("old-1", 4, 4, base)
+	oldMS2 := newTestMachineSet("old-2", 4, 4, base.Add(time.Minute))
+	newMS := newTestMachineSet("new", 2, 2, base.Add(2*time.Minute))
+
+	dc := &MachineDeploymentControllerImpl{
+		machineClient: &fakeClientset{},
+		recorder:      record.NewFakeRecorder(10),
+	}
+
+	// allowedSize is Spec.Replicas(15) + MaxSurge(1) = 16 while old machine sets still hold
+	// replicas; the 6 added replicas are shared out by current size, newest-first on ties, with
+	// the rounding leftover landing on the machine set sorted first: old-2 is tied with old-1 at
+	// size 4 but is the newer of the two, so it receives both its even share and the leftover.
+	if err := dc.scale(d, []*v1alpha1.MachineSet{oldMS1, oldMS2, newMS}, newMS); err != nil {
+		t.Fatalf("scale() returned error: %v", err)
+	}
+	assertReplicas(t, oldMS2, 7)
+	assertReplicas(t, oldMS1, 6)
+	assertReplicas(t, newMS, 3)
+}
+
+func TestScaleDistributesProportionallyOnScaleDownDuringRollout(t *testing.T) {
+	replicas := int32(6)
+	d := &v1alpha1.MachineDeployment{
+		Spec: v1alpha1.MachineDeploymentSpec{
+			Replicas: &replicas,
+			Strategy: v1alpha1.MachineDeploymentStrategy{
+				Type: common.RollingUpdateMachineDeploymentStrategyType,
+				RollingUpdate: &v1alpha1.MachineRollingUpdateDeployment{
+					MaxSurge:       intstrPtr(0),
+					MaxUnavailable: intstrPtr(1),
+				},
+			},
+		},
+	}
+
+	base := time.Unix(1600000000, 0)
+	oldMS1 := newTestMachineSet("old-1", 4, 4, base)
+	oldMS2 := newTestMachineSet("old-2", 2, 2, base.Add(time.Minute))
+	newMS := newTestMachineSet("new", 4, 4, base.Add(2*time.Minute))
+
+	dc := &MachineDeploymentControllerImpl{
+		machineClient: &fakeClientset{},
+		recorder:      record.NewFakeRecorder(10),
+	}
+
+	// allowedSize is Spec.Replicas(6) + MaxSurge(0) = 6; all 3 machine sets are fully healthy, so
+	// the 4 replicas to remove are shared out proportionally rather than drained from one set.
+	if err := dc.scale(d, []*v1alpha1.MachineSet{oldMS1, oldMS2, newMS}, newMS); err != nil {
+		t.Fatalf("scale() returned error: %v", err)
+	}
+	assertReplicas(t, oldMS1, 2)
+	assertReplicas(t, oldMS2, 2)
+	assertReplicas(t, newMS, 2)
+}
+
+func TestScaleAssignsRoundingLeftoverToFirstSortedMachineSet(t *testing.T) {
+	replicas := int32(10)
+	d := &v1alpha1.MachineDeployment{
+		Spec: v1alpha1.MachineDeploymentSpec{
+			Replicas: &replicas,
+			Strategy: v1alpha1.MachineDeploymentStrategy{
+				Type: common.RollingUpdateMachineDeploymentStrategyType,
+				RollingUpdate: &v1alpha1.MachineRollingUpdateDeployment{
+					MaxSurge:       intstrPtr(0),
+					MaxUnavailable: intstrPtr(1),
+				},
+			},
+		},
+	}
+
+	base := time.Unix(1600000000, 0)
+	third := newTestMachineSet("third", 1, 1, base)
+	second := newTestMachineSet("second", 1, 1, base.Add(time.Minute))
+	first := newTestMachineSet("first", 1, 1, base.Add(2*time.Minute))
+
+	dc := &MachineDeploymentControllerImpl{
+		machineClient: &fakeClientset{},
+		recorder:      record.NewFakeRecorder(10),
+	}
+
+	// All 3 machine sets are tied at size 1, so on this scale-up they sort newest-first: first,
+	// second, third. Each gets an equal integer share and the rounding leftover goes to whichever
+	// machine set sorted first, here "first".
+	if err := dc.scale(d, []*v1alpha1.MachineSet{second, third, first}, first); err != nil {
+		t.Fatalf("scale() returned error: %v", err)
+	}
+	assertReplicas(t, first, 4)
+	assertReplicas(t, second, 3)
+	assertReplicas(t, third, 3)
+}
@@ -1,17 +1,44 @@
 package machinedeployment
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/golang/glog"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 
 	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+	dutil "sigs.k8s.io/cluster-api/pkg/controller/machinedeployment/util"
+)
+
+const (
+	// NewMachineSetReason is added when the new machine set has finished rolling out and become
+	// the sole owner of the deployment's Machines.
+	NewMachineSetReason = "NewMachineSetAvailable"
+	// MachineSetUpdatedReason is the Progressing reason of an incomplete rollout once the deployment
+	// already has a Progressing condition, whether or not a machine set was scaled in this pass.
+	MachineSetUpdatedReason = "MachineSetUpdated"
+	// NewMachineSetCreatedReason is the Progressing reason of an incomplete rollout while the
+	// deployment has no Progressing condition yet, whether or not a machine set was created.
+	NewMachineSetCreatedReason = "NewMachineSetCreated"
+	// ProgressDeadlineExceededReason is added when the deployment fails to make progress within
+	// Spec.ProgressDeadlineSeconds.
+	ProgressDeadlineExceededReason = "ProgressDeadlineExceeded"
+	// DeploymentPausedReason is added to the Progressing condition while Spec.Paused is true.
+	DeploymentPausedReason = "DeploymentPaused"
 )
 
+// ErrProgressDeadlineExceeded is returned by ensureStatus once a deployment has exceeded its
+// ProgressDeadlineSeconds without making progress, so that the caller requeues it.
+var ErrProgressDeadlineExceeded = fmt.Errorf("machine deployment exceeded its progress deadline")
+
 func (c *MachineDeploymentControllerImpl) ensureStatus(md *v1alpha1.MachineDeployment, newMS *v1alpha1.MachineSet, oldMSs []*v1alpha1.MachineSet) (*v1alpha1.MachineDeployment, error) {
 
 	newStatus := v1alpha1.MachineDeploymentStatus{ObservedGeneration: md.Generation,
-		UpdatedReplicas: newMS.Status.Replicas}
+		UpdatedReplicas: newMS.Status.Replicas,
+		Conditions:      md.Status.Conditions}
 
 	allOwnedMS := append(oldMSs, newMS)
 	for _, ms := range allOwnedMS {
@@ -25,11 +52,92 @@ func (c *MachineDeploymentControllerImpl) ensureStatus(md *v1alpha1.MachineDeplo
 		newStatus.UnavailableReplicas = 0
 	}
 
+	c.setAvailableCondition(md, &newStatus)
+	c.setProgressingCondition(md, &newStatus, newMS, allOwnedMS)
+	c.setReplicaFailureCondition(&newStatus, newMS)
+
+	deadlineExceeded := c.progressDeadlineExceeded(md, &newStatus)
+
 	if !equality.Semantic.DeepEqual(md.Status, newStatus) {
 		md.Status = newStatus
 		glog.V(4).Infof("Updating status of machineDeployment %s/%s", md.Namespace, md.Name)
-		return c.machineClient.ClusterV1alpha1().MachineDeployments(md.Namespace).UpdateStatus(md)
+		updated, err := c.machineClient.ClusterV1alpha1().MachineDeployments(md.Namespace).UpdateStatus(md)
+		if err != nil {
+			return updated, err
+		}
+		md = updated
 	}
 
+	if deadlineExceeded {
+		return md, ErrProgressDeadlineExceeded
+	}
 	return md, nil
 }
+
+func (c *MachineDeploymentControllerImpl) setAvailableCondition(md *v1alpha1.MachineDeployment, newStatus *v1alpha1.MachineDeploymentStatus) {
+	minAvailable := *md.Spec.Replicas - dutil.MaxUnavailable(*md)
+	if newStatus.AvailableReplicas >= minAvailable {
+		dutil.SetMachineDeploymentCondition(newStatus, *dutil.NewMachineDeploymentCondition(v1alpha1.MachineDeploymentAvailable, corev1.ConditionTrue, "MinimumReplicasAvailable", "Deployment has minimum availability."))
+	} else {
+		dutil.SetMachineDeploymentCondition(newStatus, *dutil.NewMachineDeploymentCondition(v1alpha1.MachineDeploymentAvailable, corev1.ConditionFalse, "MinimumReplicasUnavailable", "Deployment does not have minimum availability."))
+	}
+}
+
+func (c *MachineDeploymentControllerImpl) setProgressingCondition(md *v1alpha1.MachineDeployment, newStatus *v1alpha1.MachineDeploymentStatus, newMS *v1alpha1.MachineSet, allMSs []*v1alpha1.MachineSet) {
+	if md.Spec.Paused {
+		dutil.SetMachineDeploymentCondition(newStatus, *dutil.NewMachineDeploymentCondition(v1alpha1.MachineDeploymentProgressing, corev1.ConditionUnknown, DeploymentPausedReason, "Deployment is paused"))
+		return
+	}
+
+	if dutil.DeploymentComplete(md, newStatus) {
+		dutil.SetMachineDeploymentCondition(newStatus, *dutil.NewMachineDeploymentCondition(v1alpha1.MachineDeploymentProgressing, corev1.ConditionTrue, NewMachineSetReason, fmt.Sprintf("MachineSet %q has successfully progressed.", newMS.Name)))
+		return
+	}
+
+	currentCond := dutil.GetMachineDeploymentCondition(md.Status, v1alpha1.MachineDeploymentProgressing)
+	reason := NewMachineSetCreatedReason
+	if currentCond != nil {
+		reason = MachineSetUpdatedReason
+	}
+	dutil.SetMachineDeploymentCondition(newStatus, *dutil.NewMachineDeploymentCondition(v1alpha1.MachineDeploymentProgressing, corev1.ConditionTrue, reason, fmt.Sprintf("MachineSet %q is progressing.", newMS.Name)))
+}
+
+func (c *MachineDeploymentControllerImpl) setReplicaFailureCondition(newStatus *v1alpha1.MachineDeploymentStatus, newMS *v1alpha1.MachineSet) {
+	if newMS.Status.ErrorReason == nil && newMS.Status.ErrorMessage == nil {
+		dutil.RemoveMachineDeploymentCondition(newStatus, v1alpha1.MachineDeploymentReplicaFailure)
+		return
+	}
+
+	reason, message := "MachineSetError", ""
+	if newMS.Status.ErrorReason != nil {
+		reason = *newMS.Status.ErrorReason
+	}
+	if newMS.Status.ErrorMessage != nil {
+		message = *newMS.Status.ErrorMessage
+	}
+	dutil.SetMachineDeploymentCondition(newStatus, *dutil.NewMachineDeploymentCondition(v1alpha1.MachineDeploymentReplicaFailure, corev1.ConditionTrue, reason, message))
+}
+
+// progressDeadlineExceeded reports whether md's Progressing condition has been sitting without an
+// update for longer than Spec.ProgressDeadlineSeconds, flipping it to False if so.
+func (c *MachineDeploymentControllerImpl) progressDeadlineExceeded(md *v1alpha1.MachineDeployment, newStatus *v1alpha1.MachineDeploymentStatus) bool {
+	if md.Spec.Paused || md.Spec.ProgressDeadlineSeconds == nil {
+		return false
+	}
+	if dutil.DeploymentComplete(md, newStatus) {
+		return false
+	}
+
+	cond := dutil.GetMachineDeploymentCondition(*newStatus, v1alpha1.MachineDeploymentProgressing)
+	if cond == nil || cond.Reason == ProgressDeadlineExceededReason {
+		return cond != nil && cond.Reason == ProgressDeadlineExceededReason
+	}
+
+	deadline := time.Duration(*md.Spec.ProgressDeadlineSeconds) * time.Second
+	if time.Since(cond.LastUpdateTime.Time) < deadline {
+		return false
+	}
+
+	dutil.SetMachineDeploymentCondition(newStatus, *dutil.NewMachineDeploymentCondition(v1alpha1.MachineDeploymentProgressing, corev1.ConditionFalse, ProgressDeadlineExceededReason, fmt.Sprintf("MachineDeployment %q has timed out progressing.", md.Name)))
+	return true
+}
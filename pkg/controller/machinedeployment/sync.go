@@ -0,0 +1,46 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machinedeployment
+
+import (
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+)
+
+// sync reconciles the size of a MachineDeployment's machine sets without triggering a rollout. It
+// is used for a paused deployment, matching `kubectl rollout pause` semantics for apps/v1
+// Deployments: the deployment's machine sets stay sized correctly, but no new machine set is
+// created or promoted until the deployment is resumed.
+func (dc *MachineDeploymentControllerImpl) sync(d *v1alpha1.MachineDeployment, msList []*v1alpha1.MachineSet, machineMap map[types.UID]*v1alpha1.MachineList) error {
+	newMS, oldMSs, err := dc.getAllMachineSetsAndSyncRevision(d, msList, machineMap, false)
+	if err != nil {
+		return err
+	}
+	if newMS == nil {
+		// No machine set matches the current template yet; there is nothing to scale until the
+		// deployment is resumed and a rollout creates one.
+		return nil
+	}
+
+	if err := dc.scale(d, msList, newMS); err != nil {
+		return err
+	}
+
+	_, err = dc.ensureStatus(d, newMS, oldMSs)
+	return err
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+)
+
+// NewMachineDeploymentCondition creates a new machine deployment condition.
+func NewMachineDeploymentCondition(condType v1alpha1.MachineDeploymentConditionType, status corev1.ConditionStatus, reason, message string) *v1alpha1.MachineDeploymentCondition {
+	return &v1alpha1.MachineDeploymentCondition{
+		Type:               condType,
+		Status:             status,
+		LastUpdateTime:     metav1.Now(),
+		LastTransitionTime: metav1.Now(),
+		Reason:             reason,
+		Message:            message,
+	}
+}
+
+// GetMachineDeploymentCondition returns the condition with the given type, if it exists.
+func GetMachineDeploymentCondition(status v1alpha1.MachineDeploymentStatus, condType v1alpha1.MachineDeploymentConditionType) *v1alpha1.MachineDeploymentCondition {
+	for i := range status.Conditions {
+		c := status.Conditions[i]
+		if c.Type == condType {
+			return &c
+		}
+	}
+	return nil
+}
+
+// SetMachineDeploymentCondition updates the deployment status to include the given condition. If
+// a condition of the same type already exists with the same status and reason, LastUpdateTime and
+// LastTransitionTime are left untouched so that repeated reconciles do not churn the status; a
+// changed message alone is therefore not applied. If the status changes, LastTransitionTime is
+// bumped; if only the reason changes, LastTransitionTime is preserved.
+func SetMachineDeploymentCondition(status *v1alpha1.MachineDeploymentStatus, condition v1alpha1.MachineDeploymentCondition) {
+	currentCond := GetMachineDeploymentCondition(*status, condition.Type)
+	if currentCond != nil && currentCond.Status == condition.Status && currentCond.Reason == condition.Reason {
+		return
+	}
+	if currentCond != nil && currentCond.Status == condition.Status {
+		condition.LastTransitionTime = currentCond.LastTransitionTime
+	}
+	newConditions := filterOutCondition(status.Conditions, condition.Type)
+	status.Conditions = append(newConditions, condition)
+}
+
+// RemoveMachineDeploymentCondition removes the condition with the given type from the status.
+func RemoveMachineDeploymentCondition(status *v1alpha1.MachineDeploymentStatus, condType v1alpha1.MachineDeploymentConditionType) {
+	status.Conditions = filterOutCondition(status.Conditions, condType)
+}
+
+func filterOutCondition(conditions []v1alpha1.MachineDeploymentCondition, condType v1alpha1.MachineDeploymentConditionType) []v1alpha1.MachineDeploymentCondition {
+	var newConditions []v1alpha1.MachineDeploymentCondition
+	for _, c := range conditions {
+		if c.Type == condType {
+			continue
+		}
+		newConditions = append(newConditions, c)
+	}
+	return newConditions
+}
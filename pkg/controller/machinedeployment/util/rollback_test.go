@@ -0,0 +1,120 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"testing"
+
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+)
+
+func machineSetWithRevision(name, revision string) *v1alpha1.MachineSet {
+	ms := &v1alpha1.MachineSet{}
+	ms.Name = name
+	if revision != "" {
+		ms.Annotations = map[string]string{RevisionAnnotation: revision}
+	}
+	return ms
+}
+
+func TestMaxRevision(t *testing.T) {
+	tests := []struct {
+		name string
+		mss  []*v1alpha1.MachineSet
+		want int64
+	}{
+		{
+			name: "no machine sets",
+			mss:  nil,
+			want: 0,
+		},
+		{
+			name: "missing annotation is treated as revision 0",
+			mss:  []*v1alpha1.MachineSet{machineSetWithRevision("ms1", "")},
+			want: 0,
+		},
+		{
+			name: "unparsable annotation is skipped",
+			mss:  []*v1alpha1.MachineSet{machineSetWithRevision("ms1", "not-a-number"), machineSetWithRevision("ms2", "2")},
+			want: 2,
+		},
+		{
+			name: "picks the highest revision",
+			mss: []*v1alpha1.MachineSet{
+				machineSetWithRevision("ms1", "1"),
+				machineSetWithRevision("ms2", "3"),
+				machineSetWithRevision("ms3", "2"),
+			},
+			want: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MaxRevision(tt.mss); got != tt.want {
+				t.Errorf("MaxRevision() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLastRevision(t *testing.T) {
+	tests := []struct {
+		name string
+		mss  []*v1alpha1.MachineSet
+		want int64
+	}{
+		{
+			name: "single machine set has no prior revision",
+			mss:  []*v1alpha1.MachineSet{machineSetWithRevision("ms1", "1")},
+			want: 0,
+		},
+		{
+			name: "returns the revision before the current one",
+			mss: []*v1alpha1.MachineSet{
+				machineSetWithRevision("ms1", "1"),
+				machineSetWithRevision("ms2", "2"),
+				machineSetWithRevision("ms3", "3"),
+			},
+			want: 2,
+		},
+		{
+			name: "tie at the top revision means last revision equals current",
+			mss: []*v1alpha1.MachineSet{
+				machineSetWithRevision("ms1", "5"),
+				machineSetWithRevision("ms2", "5"),
+			},
+			want: 5,
+		},
+		{
+			name: "missing annotations are ignored",
+			mss: []*v1alpha1.MachineSet{
+				machineSetWithRevision("ms1", ""),
+				machineSetWithRevision("ms2", "4"),
+			},
+			want: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := LastRevision(tt.mss); got != tt.want {
+				t.Errorf("LastRevision() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
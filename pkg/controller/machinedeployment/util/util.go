@@ -0,0 +1,330 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package util provides helpers shared by the machinedeployment controller that mirror the
+// semantics of k8s.io/kubernetes/pkg/controller/deployment/util for apps/v1 Deployments.
+package util
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/golang/glog"
+
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/util/integer"
+
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/common"
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+)
+
+// RevisionAnnotation is the revision annotation of a machine deployment's machine sets which records
+// its rollout sequence.
+const RevisionAnnotation = "deployment.kubernetes.io/revision"
+
+// MachineSetsByCreationTimestamp sorts a list of MachineSet by creation timestamp, using their names
+// as a tie breaker.
+type MachineSetsByCreationTimestamp []*v1alpha1.MachineSet
+
+func (o MachineSetsByCreationTimestamp) Len() int      { return len(o) }
+func (o MachineSetsByCreationTimestamp) Swap(i, j int) { o[i], o[j] = o[j], o[i] }
+func (o MachineSetsByCreationTimestamp) Less(i, j int) bool {
+	if o[i].CreationTimestamp.Equal(&o[j].CreationTimestamp) {
+		return o[i].Name < o[j].Name
+	}
+	return o[i].CreationTimestamp.Before(&o[j].CreationTimestamp)
+}
+
+// GetReplicaCountForMachineSets returns the sum of Replicas of the given machine sets.
+func GetReplicaCountForMachineSets(machineSets []*v1alpha1.MachineSet) int32 {
+	totalReplicas := int32(0)
+	for _, ms := range machineSets {
+		if ms != nil && ms.Spec.Replicas != nil {
+			totalReplicas += *ms.Spec.Replicas
+		}
+	}
+	return totalReplicas
+}
+
+// GetActualReplicaCountForMachineSets returns the sum of actual replicas of the given machine sets.
+func GetActualReplicaCountForMachineSets(machineSets []*v1alpha1.MachineSet) int32 {
+	totalReplicas := int32(0)
+	for _, ms := range machineSets {
+		if ms != nil {
+			totalReplicas += ms.Status.Replicas
+		}
+	}
+	return totalReplicas
+}
+
+// GetAvailableReplicaCountForMachineSets returns the sum of available replicas of the given machine sets.
+func GetAvailableReplicaCountForMachineSets(machineSets []*v1alpha1.MachineSet) int32 {
+	totalAvailableReplicas := int32(0)
+	for _, ms := range machineSets {
+		if ms != nil {
+			totalAvailableReplicas += ms.Status.AvailableReplicas
+		}
+	}
+	return totalAvailableReplicas
+}
+
+// MaxUnavailable returns the maximum unavailable Machines a rolling deployment can take.
+func MaxUnavailable(deployment v1alpha1.MachineDeployment) int32 {
+	if !IsRollingUpdate(&deployment) || *(deployment.Spec.Replicas) == 0 {
+		return int32(0)
+	}
+	_, maxUnavailable, err := ResolveFenceposts(deployment.Spec.Strategy.RollingUpdate.MaxSurge,
+		deployment.Spec.Strategy.RollingUpdate.MaxUnavailable, *(deployment.Spec.Replicas))
+	if err != nil {
+		return int32(0)
+	}
+	if maxUnavailable > *deployment.Spec.Replicas {
+		return *deployment.Spec.Replicas
+	}
+	return maxUnavailable
+}
+
+// MaxSurge returns the maximum surge Machines a rolling deployment can take.
+func MaxSurge(deployment v1alpha1.MachineDeployment) int32 {
+	if !IsRollingUpdate(&deployment) {
+		return int32(0)
+	}
+	maxSurge, _, err := ResolveFenceposts(deployment.Spec.Strategy.RollingUpdate.MaxSurge,
+		deployment.Spec.Strategy.RollingUpdate.MaxUnavailable, *(deployment.Spec.Replicas))
+	if err != nil {
+		return int32(0)
+	}
+	return maxSurge
+}
+
+// IsRollingUpdate returns true if the strategy type is RollingUpdate.
+func IsRollingUpdate(deployment *v1alpha1.MachineDeployment) bool {
+	return deployment.Spec.Strategy.Type == common.RollingUpdateMachineDeploymentStrategyType
+}
+
+// ResolveFenceposts resolves both maxSurge and maxUnavailable from the given percentage or
+// absolute number of replicas, using the same rules as apps/v1 Deployments.
+func ResolveFenceposts(maxSurge, maxUnavailable *intstr.IntOrString, desired int32) (int32, int32, error) {
+	surge, err := intstr.GetValueFromIntOrPercent(maxSurge, int(desired), true)
+	if err != nil {
+		return 0, 0, err
+	}
+	unavailable, err := intstr.GetValueFromIntOrPercent(maxUnavailable, int(desired), false)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if surge == 0 && unavailable == 0 {
+		unavailable = 1
+	}
+
+	return int32(surge), int32(unavailable), nil
+}
+
+// NewMSNewReplicas calculates the number of replicas a machine deployment's new machine set should
+// have. Depending on the deployment's strategy, the new machine set's number of replicas is bounded
+// by the deployment's MaxSurge to ensure availability.
+func NewMSNewReplicas(deployment *v1alpha1.MachineDeployment, allMSs []*v1alpha1.MachineSet, newMS *v1alpha1.MachineSet) (int32, error) {
+	switch deployment.Spec.Strategy.Type {
+	case common.RollingUpdateMachineDeploymentStrategyType:
+		maxSurge, err := intstr.GetValueFromIntOrPercent(deployment.Spec.Strategy.RollingUpdate.MaxSurge, int(*(deployment.Spec.Replicas)), true)
+		if err != nil {
+			return 0, err
+		}
+		currentMachineCount := GetReplicaCountForMachineSets(allMSs)
+		if currentMachineCount >= *(deployment.Spec.Replicas) {
+			return *(newMS.Spec.Replicas), nil
+		}
+
+		scaleUpCount := int32(maxSurge) - (*(deployment.Spec.Replicas) - currentMachineCount)
+		if scaleUpCount > *(deployment.Spec.Replicas)-currentMachineCount {
+			scaleUpCount = *(deployment.Spec.Replicas) - currentMachineCount
+		}
+		newReplicasCount := *(newMS.Spec.Replicas) + scaleUpCount
+		if newReplicasCount > *(deployment.Spec.Replicas) {
+			return 0, fmt.Errorf("when calculating the new machine set's replica count, more replicas than expected were calculated")
+		}
+		return newReplicasCount, nil
+	case common.RecreateMachineDeploymentStrategyType:
+		return *(deployment.Spec.Replicas), nil
+	default:
+		return 0, fmt.Errorf("machine deployment type %v isn't supported", deployment.Spec.Strategy.Type)
+	}
+}
+
+// FindNewMachineSet returns the new MS this given deployment targets, i.e. the one whose template
+// matches the deployment's, or nil if it doesn't exist.
+func FindNewMachineSet(deployment *v1alpha1.MachineDeployment, msList []*v1alpha1.MachineSet) *v1alpha1.MachineSet {
+	sort.Sort(MachineSetsByCreationTimestamp(msList))
+	for i := range msList {
+		if EqualMachineTemplate(&msList[i].Spec.Template, &deployment.Spec.Template) {
+			return msList[i]
+		}
+	}
+	return nil
+}
+
+// FindOldMachineSets returns the old machine sets targeted by the given deployment, excluding the
+// new machine set.
+func FindOldMachineSets(deployment *v1alpha1.MachineDeployment, msList []*v1alpha1.MachineSet, newMS *v1alpha1.MachineSet) []*v1alpha1.MachineSet {
+	var oldMSs []*v1alpha1.MachineSet
+	for _, ms := range msList {
+		if newMS != nil && ms.UID == newMS.UID {
+			continue
+		}
+		oldMSs = append(oldMSs, ms)
+	}
+	return oldMSs
+}
+
+// EqualMachineTemplate returns true if two MachineTemplateSpecs have equal Specs. The templates'
+// ObjectMeta (labels, annotations and the rest) is not compared.
+func EqualMachineTemplate(a, b *v1alpha1.MachineTemplateSpec) bool {
+	return reflect.DeepEqual(a.Spec, b.Spec)
+}
+
+// Revision returns the revision number of the given MachineSet, as recorded in its
+// RevisionAnnotation. A MachineSet with no such annotation has revision 0.
+func Revision(ms *v1alpha1.MachineSet) (int64, error) {
+	v, ok := ms.Annotations[RevisionAnnotation]
+	if !ok {
+		return 0, nil
+	}
+	return strconv.ParseInt(v, 10, 64)
+}
+
+// MaxRevision returns the highest revision number among the given machine sets.
+func MaxRevision(allMSs []*v1alpha1.MachineSet) int64 {
+	max := int64(0)
+	for _, ms := range allMSs {
+		if v, err := Revision(ms); err != nil {
+			glog.V(4).Infof("Couldn't parse revision for machine set %#v, deployment controller will skip it when reconciling revisions: %v", ms, err)
+		} else if v > max {
+			max = v
+		}
+	}
+	return max
+}
+
+// LastRevision returns the second-highest revision found among the given machine sets, i.e. the
+// revision a rollback to revision 0 targets. Each machine set counts separately, so when two of
+// them share the highest revision, that revision is returned.
+func LastRevision(allMSs []*v1alpha1.MachineSet) int64 {
+	max, secMax := int64(0), int64(0)
+	for _, ms := range allMSs {
+		if v, err := Revision(ms); err == nil {
+			switch {
+			case max < v:
+				secMax = max
+				max = v
+			case secMax < v:
+				secMax = v
+			}
+		}
+	}
+	return secMax
+}
+
+// SetFromMachineSetTemplate copies the given template into the deployment's spec, as done when
+// rolling back to a prior revision.
+func SetFromMachineSetTemplate(deployment *v1alpha1.MachineDeployment, template v1alpha1.MachineTemplateSpec) *v1alpha1.MachineDeployment {
+	deployment.Spec.Template.ObjectMeta = template.ObjectMeta
+	deployment.Spec.Template.Spec = template.Spec
+	return deployment
+}
+
+// SetDeploymentRevision records the given revision on the deployment's RevisionAnnotation.
+func SetDeploymentRevision(deployment *v1alpha1.MachineDeployment, revision string) {
+	if deployment.Annotations == nil {
+		deployment.Annotations = make(map[string]string)
+	}
+	deployment.Annotations[RevisionAnnotation] = revision
+}
+
+// MachineSetsBySizeNewer sorts a list of MachineSet by size in descending order, breaking ties by
+// placing the newer MachineSet first. Used when distributing a scale-up proportionally; any
+// leftover replicas land on the MachineSet sorted first, i.e. the largest, newest among ties.
+type MachineSetsBySizeNewer []*v1alpha1.MachineSet
+
+func (o MachineSetsBySizeNewer) Len() int      { return len(o) }
+func (o MachineSetsBySizeNewer) Swap(i, j int) { o[i], o[j] = o[j], o[i] }
+func (o MachineSetsBySizeNewer) Less(i, j int) bool {
+	if GetReplicaCountForMachineSets([]*v1alpha1.MachineSet{o[i]}) == GetReplicaCountForMachineSets([]*v1alpha1.MachineSet{o[j]}) {
+		return o[j].CreationTimestamp.Before(&o[i].CreationTimestamp)
+	}
+	return GetReplicaCountForMachineSets([]*v1alpha1.MachineSet{o[i]}) > GetReplicaCountForMachineSets([]*v1alpha1.MachineSet{o[j]})
+}
+
+// MachineSetsBySizeOlder sorts a list of MachineSet by size in descending order, breaking ties by
+// placing the older MachineSet first. Used when distributing a scale-down proportionally; any
+// leftover replicas are removed from the MachineSet sorted first, i.e. the largest, oldest among
+// ties.
+type MachineSetsBySizeOlder []*v1alpha1.MachineSet
+
+func (o MachineSetsBySizeOlder) Len() int      { return len(o) }
+func (o MachineSetsBySizeOlder) Swap(i, j int) { o[i], o[j] = o[j], o[i] }
+func (o MachineSetsBySizeOlder) Less(i, j int) bool {
+	if GetReplicaCountForMachineSets([]*v1alpha1.MachineSet{o[i]}) == GetReplicaCountForMachineSets([]*v1alpha1.MachineSet{o[j]}) {
+		return o[i].CreationTimestamp.Before(&o[j].CreationTimestamp)
+	}
+	return GetReplicaCountForMachineSets([]*v1alpha1.MachineSet{o[i]}) > GetReplicaCountForMachineSets([]*v1alpha1.MachineSet{o[j]})
+}
+
+// GetReplicaSetFraction returns the number of replicas to add to (or, if negative, remove from) ms
+// so that it holds its fair share of allowedSize (Spec.Replicas [+ MaxSurge] of the deployment),
+// given that allMSsReplicas machines currently exist across all of the deployment's machine sets
+// combined.
+func GetReplicaSetFraction(ms v1alpha1.MachineSet, d v1alpha1.MachineDeployment, allowedSize, allMSsReplicas int32) int32 {
+	if allowedSize == 0 {
+		// Scaling down to zero: the fraction is the whole (negative) current size.
+		return -*(ms.Spec.Replicas)
+	}
+	if allMSsReplicas == 0 {
+		return 0
+	}
+
+	newMSsize := (float64(*(ms.Spec.Replicas)) * float64(allowedSize)) / float64(allMSsReplicas)
+	return int32(math.Round(newMSsize)) - *(ms.Spec.Replicas)
+}
+
+// GetProportion computes the number of replicas that should be added to (or removed from) ms as
+// its proportional share of deploymentReplicasToAdd, given that deploymentReplicasAdded have
+// already been allocated to other machine sets in this pass.
+func GetProportion(ms *v1alpha1.MachineSet, d v1alpha1.MachineDeployment, allowedSize, allMSsReplicas, deploymentReplicasToAdd, deploymentReplicasAdded int32) int32 {
+	if ms == nil || *(ms.Spec.Replicas) == 0 || deploymentReplicasToAdd == 0 || deploymentReplicasToAdd == deploymentReplicasAdded {
+		return int32(0)
+	}
+
+	msFraction := GetReplicaSetFraction(*ms, d, allowedSize, allMSsReplicas)
+	allowed := deploymentReplicasToAdd - deploymentReplicasAdded
+
+	if deploymentReplicasToAdd > 0 {
+		return integer.Int32Min(msFraction, allowed)
+	}
+	return integer.Int32Max(msFraction, allowed)
+}
+
+// DeploymentComplete considers a deployment to be complete once all of its desired replicas are
+// updated, available and no old machines are running.
+func DeploymentComplete(deployment *v1alpha1.MachineDeployment, newStatus *v1alpha1.MachineDeploymentStatus) bool {
+	return newStatus.UpdatedReplicas == *(deployment.Spec.Replicas) &&
+		newStatus.Replicas == *(deployment.Spec.Replicas) &&
+		newStatus.AvailableReplicas == *(deployment.Spec.Replicas) &&
+		newStatus.ObservedGeneration >= deployment.Generation
+}
@@ -45,6 +45,15 @@ func ValidateMachineDeploymentSpec(spec *v1alpha1.MachineDeploymentSpec, fldPath
 	}
 
 	allErrs = append(allErrs, ValidateMachineDeploymentStrategy(&spec.Strategy, fldPath.Child("strategy"))...)
+
+	if spec.RollbackTo != nil && spec.RollbackTo.Revision < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("rollbackTo", "revision"), spec.RollbackTo.Revision, "must not be negative"))
+	}
+
+	if spec.RollbackTo != nil && spec.Paused {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("rollbackTo"), spec.RollbackTo, "cannot process a rollback while the deployment is paused"))
+	}
+
 	return allErrs
 }
 
@@ -55,6 +64,10 @@ func ValidateMachineDeploymentStrategy(strategy *v1alpha1.MachineDeploymentStrat
 		if strategy.RollingUpdate != nil {
 			allErrs = append(allErrs, ValidateMachineRollingUpdateDeployment(strategy.RollingUpdate, fldPath.Child("rollingUpdate"))...)
 		}
+	case common.RecreateMachineDeploymentStrategyType:
+		if strategy.RollingUpdate != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("rollingUpdate"), strategy.RollingUpdate, "may not be specified when strategy `type` is 'Recreate'"))
+		}
 	default:
 		allErrs = append(allErrs, field.Invalid(fldPath.Child("Type"), strategy.Type, "is an invalid type"))
 	}
@@ -132,7 +145,8 @@ func DefaultingFunction(o interface{}) {
 		obj.Spec.Strategy.Type = common.RollingUpdateMachineDeploymentStrategyType
 	}
 
-	// Default RollingUpdate strategy only if strategy type is RollingUpdate.
+	// Only default the RollingUpdate params when the strategy is RollingUpdate; a Recreate
+	// deployment never carries RollingUpdate configuration.
 	if obj.Spec.Strategy.Type == common.RollingUpdateMachineDeploymentStrategyType {
 		if obj.Spec.Strategy.RollingUpdate == nil {
 			obj.Spec.Strategy.RollingUpdate = &v1alpha1.MachineRollingUpdateDeployment{}
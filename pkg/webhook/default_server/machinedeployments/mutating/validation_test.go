@@ -0,0 +1,87 @@
+package mutating
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/common"
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+)
+
+func validMachineDeploymentSpec() *v1alpha1.MachineDeploymentSpec {
+	replicas := int32(1)
+	maxSurge := intstr.FromInt(1)
+	maxUnavailable := intstr.FromInt(0)
+	return &v1alpha1.MachineDeploymentSpec{
+		Replicas: &replicas,
+		Selector: metav1.LabelSelector{MatchLabels: map[string]string{"foo": "bar"}},
+		Template: v1alpha1.MachineTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"foo": "bar"}},
+		},
+		Strategy: v1alpha1.MachineDeploymentStrategy{
+			Type: common.RollingUpdateMachineDeploymentStrategyType,
+			RollingUpdate: &v1alpha1.MachineRollingUpdateDeployment{
+				MaxSurge:       &maxSurge,
+				MaxUnavailable: &maxUnavailable,
+			},
+		},
+	}
+}
+
+func TestValidateMachineDeploymentSpec(t *testing.T) {
+	tests := []struct {
+		name    string
+		mutate  func(spec *v1alpha1.MachineDeploymentSpec)
+		wantErr bool
+	}{
+		{
+			name:    "valid spec",
+			mutate:  func(spec *v1alpha1.MachineDeploymentSpec) {},
+			wantErr: false,
+		},
+		{
+			name: "negative rollback revision is rejected",
+			mutate: func(spec *v1alpha1.MachineDeploymentSpec) {
+				spec.RollbackTo = &v1alpha1.RollbackConfig{Revision: -1}
+			},
+			wantErr: true,
+		},
+		{
+			name: "rollback on a paused deployment is rejected",
+			mutate: func(spec *v1alpha1.MachineDeploymentSpec) {
+				spec.Paused = true
+				spec.RollbackTo = &v1alpha1.RollbackConfig{Revision: 1}
+			},
+			wantErr: true,
+		},
+		{
+			name: "rollback on a non-paused deployment is allowed",
+			mutate: func(spec *v1alpha1.MachineDeploymentSpec) {
+				spec.RollbackTo = &v1alpha1.RollbackConfig{Revision: 1}
+			},
+			wantErr: false,
+		},
+		{
+			name: "pausing without a rollback request is allowed",
+			mutate: func(spec *v1alpha1.MachineDeploymentSpec) {
+				spec.Paused = true
+			},
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := validMachineDeploymentSpec()
+			tt.mutate(spec)
+
+			errs := ValidateMachineDeploymentSpec(spec, field.NewPath("spec"))
+			if (len(errs) > 0) != tt.wantErr {
+				t.Errorf("ValidateMachineDeploymentSpec() errs = %v, wantErr %v", errs, tt.wantErr)
+			}
+		})
+	}
+}